@@ -0,0 +1,62 @@
+package dolista
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/iatistas/dolista-safado/messenger"
+)
+
+// auditEntry is one append-only record of a summary mutation.
+type auditEntry struct {
+	Action    string    `firestore:"action"`
+	Message   string    `firestore:"message"`
+	AuthorID  string    `firestore:"authorId"`
+	Timestamp time.Time `firestore:"timestamp"`
+}
+
+// AuditHook appends an entry to chats/{chat}/audit for every summary mutation, so changes can
+// later be traced back to when they happened and, for adds and removes, who made them. Clear
+// entries carry no author ID, since OnClear is not given one.
+type AuditHook struct {
+	Client *firestore.Client
+}
+
+// NewAuditHook builds an AuditHook backed by client.
+func NewAuditHook(client *firestore.Client) *AuditHook {
+	return &AuditHook{Client: client}
+}
+
+func (h *AuditHook) OnAdd(ctx context.Context, item SummaryItem) error {
+	return h.append(ctx, item, "add")
+}
+
+func (h *AuditHook) OnRemove(ctx context.Context, item SummaryItem) error {
+	return h.append(ctx, item, "remove")
+}
+
+func (h *AuditHook) OnClear(ctx context.Context, chat messenger.ChatRef) error {
+	_, _, err := h.Client.Collection(auditCollectionPath(chat)).Add(ctx, auditEntry{
+		Action:    "clear",
+		Timestamp: time.Now(),
+	})
+	return err
+}
+
+func (h *AuditHook) append(ctx context.Context, item SummaryItem, action string) error {
+	chat := messenger.ChatRef{Platform: item.Platform, ID: item.ChatID}
+	_, _, err := h.Client.Collection(auditCollectionPath(chat)).Add(ctx, auditEntry{
+		Action:    action,
+		Message:   item.Message,
+		AuthorID:  item.AuthorID,
+		Timestamp: time.Now(),
+	})
+	return err
+}
+
+func auditCollectionPath(chat messenger.ChatRef) string {
+	return fmt.Sprintf("chats/%s/audit", chatKey(chat))
+}
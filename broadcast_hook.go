@@ -0,0 +1,32 @@
+package dolista
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iatistas/dolista-safado/messenger"
+)
+
+// BroadcastHook mirrors every summary mutation to a second chat, useful for shopping lists shared
+// between two paired chats.
+type BroadcastHook struct {
+	Messenger messenger.Messenger
+	Target    messenger.ChatRef
+}
+
+// NewBroadcastHook builds a BroadcastHook that mirrors changes to target via msgr.
+func NewBroadcastHook(msgr messenger.Messenger, target messenger.ChatRef) *BroadcastHook {
+	return &BroadcastHook{Messenger: msgr, Target: target}
+}
+
+func (h *BroadcastHook) OnAdd(ctx context.Context, item SummaryItem) error {
+	return h.Messenger.Send(ctx, h.Target, fmt.Sprintf("+ %s", item.Message))
+}
+
+func (h *BroadcastHook) OnRemove(ctx context.Context, item SummaryItem) error {
+	return h.Messenger.Send(ctx, h.Target, fmt.Sprintf("- %s", item.Message))
+}
+
+func (h *BroadcastHook) OnClear(ctx context.Context, chat messenger.ChatRef) error {
+	return h.Messenger.Send(ctx, h.Target, "Lista limpa.")
+}
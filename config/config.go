@@ -0,0 +1,187 @@
+// Package config loads application configuration, merging a JSON file on disk with
+// environment variable overrides, and caches the Firestore client the config builds.
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	firebase "firebase.google.com/go/v4"
+	"google.golang.org/api/option"
+)
+
+// FirebaseConfig holds the service account fields needed to authenticate with Firestore.
+type FirebaseConfig struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"project_id"`
+	PrivateKeyID            string `json:"private_key_id"`
+	PrivateKey              string `json:"private_key"`
+	ClientEmail             string `json:"client_email"`
+	ClientID                string `json:"client_id"`
+	AuthURI                 string `json:"auth_uri"`
+	TokenURI                string `json:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url"`
+}
+
+// Config represents the application config params.
+type Config struct {
+	TelegramToken     string         `json:"telegramToken"`
+	DiscordToken      string         `json:"discordToken"`
+	WebhookSecret     string         `json:"webhookSecret"`
+	AuthorizedChatIDs []int          `json:"authorizedChatIds"`
+	BroadcastChatID   int            `json:"broadcastChatId"`
+	FirebaseConfig    FirebaseConfig `json:"firebaseConfig"`
+}
+
+// Load builds the Config from, in increasing order of precedence: the legacy APP_CONFIG
+// base64-encoded-JSON env var, a JSON file at CONFIG_PATH, and finally individual env vars
+// (TELEGRAM_TOKEN, DISCORD_TOKEN, FIREBASE_CREDENTIALS_JSON, WEBHOOK_SECRET, BROADCAST_CHAT_ID).
+// Each source only overrides the fields it actually sets, so later sources fill gaps left by
+// earlier ones rather than replacing the whole Config.
+func Load() (Config, error) {
+	var cfg Config
+
+	if legacy, ok := os.LookupEnv("APP_CONFIG"); ok {
+		src, err := decodeLegacyAppConfig(legacy)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse APP_CONFIG: %w", err)
+		}
+		mergeConfig(&cfg, src)
+	}
+
+	if path, ok := os.LookupEnv("CONFIG_PATH"); ok {
+		src, err := decodeFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to load config file %q: %w", path, err)
+		}
+		mergeConfig(&cfg, src)
+	}
+
+	if err := applyEnvOverrides(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func decodeLegacyAppConfig(encoded string) (Config, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func decodeFile(path string) (Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// mergeConfig copies every field src sets into dst, leaving fields dst already has alone wherever
+// src leaves them at their zero value. This is what lets a later source (e.g. the config file)
+// fill gaps left by an earlier one (e.g. legacy APP_CONFIG) instead of an unmarshal onto the
+// shared accumulator wiping out a value the earlier source already set just because the later
+// source's JSON serializes that field as its zero value too.
+func mergeConfig(dst *Config, src Config) {
+	if src.TelegramToken != "" {
+		dst.TelegramToken = src.TelegramToken
+	}
+	if src.DiscordToken != "" {
+		dst.DiscordToken = src.DiscordToken
+	}
+	if src.WebhookSecret != "" {
+		dst.WebhookSecret = src.WebhookSecret
+	}
+	if len(src.AuthorizedChatIDs) > 0 {
+		dst.AuthorizedChatIDs = src.AuthorizedChatIDs
+	}
+	if src.BroadcastChatID != 0 {
+		dst.BroadcastChatID = src.BroadcastChatID
+	}
+	if src.FirebaseConfig != (FirebaseConfig{}) {
+		dst.FirebaseConfig = src.FirebaseConfig
+	}
+}
+
+func applyEnvOverrides(cfg *Config) error {
+	if token, ok := os.LookupEnv("TELEGRAM_TOKEN"); ok {
+		cfg.TelegramToken = token
+	}
+	if token, ok := os.LookupEnv("DISCORD_TOKEN"); ok {
+		cfg.DiscordToken = token
+	}
+	if secret, ok := os.LookupEnv("WEBHOOK_SECRET"); ok {
+		cfg.WebhookSecret = secret
+	}
+	if credsJSON, ok := os.LookupEnv("FIREBASE_CREDENTIALS_JSON"); ok {
+		var fb FirebaseConfig
+		if err := json.Unmarshal([]byte(credsJSON), &fb); err != nil {
+			return fmt.Errorf("failed to parse FIREBASE_CREDENTIALS_JSON: %w", err)
+		}
+		cfg.FirebaseConfig = fb
+	}
+	if raw, ok := os.LookupEnv("BROADCAST_CHAT_ID"); ok {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse BROADCAST_CHAT_ID: %w", err)
+		}
+		cfg.BroadcastChatID = id
+	}
+	return nil
+}
+
+var (
+	firestoreOnce   sync.Once
+	firestoreClient *firestore.Client
+	firestoreErr    error
+)
+
+// Firestore returns a process-wide cached Firestore client, building it on the first call and
+// reusing it on every subsequent one. This avoids paying Firebase app + client setup latency on
+// every single request.
+func (c Config) Firestore(ctx context.Context) (*firestore.Client, error) {
+	firestoreOnce.Do(func() {
+		firebaseConfigJSON, err := json.Marshal(c.FirebaseConfig)
+		if err != nil {
+			firestoreErr = fmt.Errorf("failed to marshal firebase config: %w", err)
+			return
+		}
+
+		app, err := firebase.NewApp(ctx, nil, option.WithCredentialsJSON(firebaseConfigJSON))
+		if err != nil {
+			firestoreErr = fmt.Errorf("failed to create firebase app: %w", err)
+			return
+		}
+
+		firestoreClient, firestoreErr = app.Firestore(ctx)
+	})
+
+	return firestoreClient, firestoreErr
+}
+
+// Close releases the cached Firestore client. Cloud Function instances are recycled rather than
+// shut down cleanly, so this only matters for local/test binaries that want a tidy exit.
+func Close() error {
+	if firestoreClient == nil {
+		return nil
+	}
+	return firestoreClient.Close()
+}
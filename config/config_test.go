@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_Precedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		appConfig  *Config
+		fileConfig *Config
+		env        map[string]string
+		wantToken  string
+		wantSecret string
+	}{
+		{
+			name:      "legacy APP_CONFIG used when nothing else is set",
+			appConfig: &Config{TelegramToken: "from-app-config", WebhookSecret: "app-config-secret"},
+			wantToken: "from-app-config", wantSecret: "app-config-secret",
+		},
+		{
+			name:       "file overrides legacy APP_CONFIG",
+			appConfig:  &Config{TelegramToken: "from-app-config", WebhookSecret: "app-config-secret"},
+			fileConfig: &Config{TelegramToken: "from-file"},
+			wantToken:  "from-file", wantSecret: "app-config-secret",
+		},
+		{
+			name:       "env var overrides file",
+			fileConfig: &Config{TelegramToken: "from-file", WebhookSecret: "file-secret"},
+			env:        map[string]string{"TELEGRAM_TOKEN": "from-env"},
+			wantToken:  "from-env", wantSecret: "file-secret",
+		},
+		{
+			name:      "env var overrides legacy APP_CONFIG",
+			appConfig: &Config{TelegramToken: "from-app-config"},
+			env:       map[string]string{"TELEGRAM_TOKEN": "from-env"},
+			wantToken: "from-env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.appConfig != nil {
+				raw, err := json.Marshal(tt.appConfig)
+				if err != nil {
+					t.Fatalf("failed to marshal app config fixture: %v", err)
+				}
+				t.Setenv("APP_CONFIG", base64.RawStdEncoding.EncodeToString(raw))
+			}
+
+			if tt.fileConfig != nil {
+				raw, err := json.Marshal(tt.fileConfig)
+				if err != nil {
+					t.Fatalf("failed to marshal file config fixture: %v", err)
+				}
+				path := filepath.Join(t.TempDir(), "config.json")
+				if err := os.WriteFile(path, raw, 0o600); err != nil {
+					t.Fatalf("failed to write config file fixture: %v", err)
+				}
+				t.Setenv("CONFIG_PATH", path)
+			}
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := Load()
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			if got.TelegramToken != tt.wantToken {
+				t.Errorf("TelegramToken = %q, want %q", got.TelegramToken, tt.wantToken)
+			}
+			if got.WebhookSecret != tt.wantSecret {
+				t.Errorf("WebhookSecret = %q, want %q", got.WebhookSecret, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestLoad_NoSourcesConfigured(t *testing.T) {
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got.TelegramToken != "" || got.WebhookSecret != "" || len(got.AuthorizedChatIDs) != 0 {
+		t.Errorf("Load() = %+v, want zero value", got)
+	}
+}
@@ -0,0 +1,34 @@
+package dolista
+
+import (
+	"context"
+	"log"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/iatistas/dolista-safado/config"
+	"github.com/iatistas/dolista-safado/messenger/discord"
+)
+
+// ListenDiscord opens a Discord gateway connection and dispatches every incoming message through
+// the same CommandRouter HandleMessage builds for Telegram, so /resumo, /r and friends behave the
+// same on both platforms. Telegram is served as a webhook inside a Cloud Function, but Discord's
+// gateway needs a long-lived connection, so this is meant to run in its own long-lived process
+// (e.g. a small worker binary invoking it once at startup) rather than inside HandleMessage.
+// ListenDiscord blocks until ctx is canceled. If the gateway connection cannot be opened it
+// returns at once with a nil error, since Receive reports that only by closing its channel.
+func ListenDiscord(ctx context.Context, client *firestore.Client, cfg config.Config) error {
+	dc, err := discord.New(cfg.DiscordToken)
+	if err != nil {
+		return err
+	}
+	registerBuiltinHooks(client, dc, cfg)
+	rt := getRouter(dc, nil, client, cfg.AuthorizedChatIDs)
+
+	for event := range dc.Receive(ctx) {
+		if _, err := rt.Dispatch(ctx, chatKey(event.Chat), event.UserID, event.Text); err != nil {
+			log.Printf("discord router: command rejected: %v\n", err)
+		}
+	}
+	return nil
+}
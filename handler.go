@@ -2,140 +2,229 @@ package dolista
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
-	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/firestore"
-	firebase "firebase.google.com/go/v4"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/iatistas/dolista-safado/config"
+	"github.com/iatistas/dolista-safado/messenger"
+	"github.com/iatistas/dolista-safado/messenger/telegram"
+	"github.com/iatistas/dolista-safado/router"
 )
 
-// Update is a Telegram object that the handler receives every time an user interacts with the bot.
-type Update struct {
-	UpdateId int     `json:"update_id"`
-	Message  Message `json:"message"`
+// SummaryItem represents one of the items that can be held by a summary list.
+type SummaryItem struct {
+	Message   string    `json:"message" firestore:"message"`
+	ChatID    string    `json:"chatId" firestore:"chatId"`
+	Platform  string    `json:"platform" firestore:"platform"`
+	ListName  string    `json:"listName" firestore:"listName"`
+	AuthorID  string    `json:"authorId" firestore:"authorId"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
 }
 
-// Message is a Telegram object that can be found in an update.
-type Message struct {
-	Text string `json:"text"`
-	Chat Chat   `json:"chat"`
+// chatState tracks per-chat bookkeeping, such as which list is currently active.
+type chatState struct {
+	ActiveList string `firestore:"activeList"`
 }
 
-// A Telegram Chat indicates the conversation to which the message belongs.
-type Chat struct {
-	Id int `json:"id"`
+// summaryList is the document that represents a named list belonging to a chat.
+type summaryList struct {
+	Name      string    `firestore:"name"`
+	CreatedAt time.Time `firestore:"createdAt"`
 }
 
-// SummaryItem represents one of the items that can be held by a summary
-type SummaryItem struct {
-	Message string `json:"message"`
-}
-
-// Config represents the application config params
-type Config struct {
-	TelegramToken  string `json:"telegramToken"`
-	FirebaseConfig struct {
-		Type                    string `json:"type"`
-		ProjectID               string `json:"project_id"`
-		PrivateKeyID            string `json:"private_key_id"`
-		PrivateKey              string `json:"private_key"`
-		ClientEmail             string `json:"client_email"`
-		ClientID                string `json:"client_id"`
-		AuthURI                 string `json:"auth_uri"`
-		TokenURI                string `json:"token_uri"`
-		AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
-		ClientX509CertURL       string `json:"client_x509_cert_url"`
-	} `json:"firebaseConfig"`
-}
+// defaultListName is the list a chat uses until it switches with /lista.
+const defaultListName = "default"
+
+// legacySummaryCollection is the single global collection every chat used to share.
+const legacySummaryCollection = "summary"
+
+// webhookSecretHeader is the header Telegram echoes back on every webhook call once a secret
+// token has been set via setWebhook, letting us reject requests that didn't come from Telegram.
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
 
 func HandleMessage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var reqBody Update
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+	update, event, err := telegram.DecodeUpdate(r.Body)
+	if err != nil {
 		log.Printf("failed to decode request body: %v\n", err)
 		return
 	}
 
-	appConfig := os.Getenv("APP_CONFIG")
-	bConfig, err := base64.RawStdEncoding.DecodeString(appConfig)
+	cfg, err := config.Load()
 	if err != nil {
-		log.Printf("failed decode app config: %v\n", err)
+		log.Printf("failed to load config: %v\n", err)
 		return
 	}
 
-	var config Config
-	err = json.Unmarshal(bConfig, &config)
-	if err != nil {
-		log.Printf("failed to parse app config: %v\n", err)
+	if cfg.WebhookSecret != "" && r.Header.Get(webhookSecretHeader) != cfg.WebhookSecret {
+		log.Printf("rejected webhook request: missing or invalid %s\n", webhookSecretHeader)
+		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	firebaseConfig, err := json.Marshal(config.FirebaseConfig)
+	client, err := cfg.Firestore(ctx)
 	if err != nil {
-		log.Printf("failed to parse firebase config: %v\n", err)
+		log.Printf("failed to get firestore client: %v\n", err)
 		return
 	}
 
-	opt := option.WithCredentialsJSON(firebaseConfig)
-	// conf := &firebase.Config{
-	// 	ProjectID:        "dolista-safado",
-	// 	ServiceAccountID: "github-actions@dolista-safado.iam.gserviceaccount.com",
-	// }
-	app, err := firebase.NewApp(ctx, nil, opt)
-	if err != nil {
-		log.Printf("failed to create firebase app: %v\n", err)
+	tg := telegram.New(cfg.TelegramToken)
+	registerBuiltinHooks(client, tg, cfg)
+
+	if update.CallbackQuery != nil {
+		chat := telegram.ChatRef(update.CallbackQuery.Message.Chat.Id)
+		if !router.IsAuthorized(authorizedChatKeys(cfg.AuthorizedChatIDs), chatKey(chat)) {
+			log.Printf("rejected callback from unauthorized chat %s\n", chat.ID)
+			return
+		}
+		if err := handleRmCallback(ctx, tg, update.CallbackQuery, client); err != nil {
+			log.Printf("failed to handle callback: %v\n", err)
+		}
 		return
 	}
 
-	client, err := app.Firestore(ctx)
+	rt := getRouter(tg, tg, client, cfg.AuthorizedChatIDs)
+	matched, err := rt.Dispatch(ctx, chatKey(event.Chat), event.UserID, event.Text)
 	if err != nil {
-		log.Printf("failed to create firestore client: %v\n", err)
+		log.Printf("router: command rejected: %v\n", err)
 		return
 	}
-	defer client.Close()
-
-	if strings.HasPrefix(reqBody.Message.Text, "/hello") {
-		handleHello(reqBody.Message.Chat.Id, reqBody.Message.Text, config.TelegramToken)
+	if !matched {
 		return
 	}
+}
 
-	if strings.HasPrefix(reqBody.Message.Text, "/safada") {
-		handleSafada(reqBody.Message.Chat.Id, reqBody.Message.Text, config.TelegramToken)
-		return
-	}
+// registerBuiltinHooksOnce guards registerBuiltinHooks so the built-in hooks are only added to
+// the pipeline once per process, no matter how many requests this instance serves.
+var registerBuiltinHooksOnce sync.Once
 
-	if strings.HasPrefix(reqBody.Message.Text, "/resumo") {
-		handleResumo(ctx, reqBody.Message.Chat.Id, config.TelegramToken, client)
-		return
+// registerBuiltinHooks wires up the hooks this bot ships with: an audit trail, always on, and a
+// broadcast to a secondary chat when one is configured. Callers that need other integrations
+// (email digests, outbound webhooks) can call RegisterHook themselves at init time.
+func registerBuiltinHooks(client *firestore.Client, msgr messenger.Messenger, cfg config.Config) {
+	registerBuiltinHooksOnce.Do(func() {
+		RegisterHook(NewAuditHook(client))
+		if cfg.BroadcastChatID != 0 {
+			RegisterHook(NewBroadcastHook(msgr, telegram.ChatRef(cfg.BroadcastChatID)))
+		}
+	})
+}
+
+// routerOnce guards buildRouter so it only runs once per process. buildRouter's middleware
+// (notably RateLimitMiddleware) keeps state across dispatches in closures it owns; rebuilding the
+// router on every request or event would hand each dispatch a fresh, empty rate limiter, making
+// the limit a no-op.
+var (
+	routerOnce   sync.Once
+	cachedRouter *router.CommandRouter
+)
+
+// getRouter builds the CommandRouter the first time it's called and returns the same instance on
+// every subsequent call, so middleware state (like the rate limiter's hit counts) actually
+// accumulates across dispatches instead of resetting every time.
+func getRouter(msgr messenger.Messenger, tg *telegram.Client, client *firestore.Client, authorizedChatIDs []int) *router.CommandRouter {
+	routerOnce.Do(func() {
+		cachedRouter = buildRouter(msgr, tg, client, authorizedChatIDs)
+	})
+	return cachedRouter
+}
+
+// buildRouter wires up every bot command behind the CommandRouter, along with the logging, rate
+// limiting and chat allowlist middleware every command should go through. Each command closure
+// reads the chat it's dispatched for from c.ChatID rather than closing over one, since the router
+// is built once and reused across every chat. `tg` is only used by the `/rm` flow, which relies on
+// Telegram-specific inline keyboards that don't fit the generic Messenger interface; pass nil for
+// platforms other than Telegram and `/rm` is left unregistered.
+func buildRouter(msgr messenger.Messenger, tg *telegram.Client, client *firestore.Client, authorizedChatIDs []int) *router.CommandRouter {
+	rt := router.New()
+	rt.Use(router.LoggingMiddleware())
+	rt.Use(router.RateLimitMiddleware(20, time.Minute))
+	rt.Use(router.AllowlistMiddleware(authorizedChatKeys(authorizedChatIDs), "/whoami"))
+
+	rt.Handle("/hello", "Diz oi.", func(ctx context.Context, c *router.Context) error {
+		return handleHello(ctx, msgr, parseChatKey(c.ChatID))
+	})
+	rt.Handle("/safada", "Quem é a safada?", func(ctx context.Context, c *router.Context) error {
+		return handleSafada(ctx, msgr, parseChatKey(c.ChatID))
+	})
+	rt.Handle("/resumo", "Mostra os itens da lista ativa.", func(ctx context.Context, c *router.Context) error {
+		return handleResumo(ctx, msgr, parseChatKey(c.ChatID), client)
+	})
+	rt.Handle("/r", "Adiciona um item à lista ativa.", func(ctx context.Context, c *router.Context) error {
+		return handleAddResumo(ctx, msgr, parseChatKey(c.ChatID), c.UserID, c.Raw, client)
+	})
+	if tg != nil {
+		rt.Handle("/rm", "Remove itens da lista ativa.", func(ctx context.Context, c *router.Context) error {
+			return handleRm(ctx, tg, parseChatKey(c.ChatID), client)
+		})
 	}
+	rt.Handle("/lista", "Troca a lista ativa da conversa.", func(ctx context.Context, c *router.Context) error {
+		return handleLista(ctx, msgr, parseChatKey(c.ChatID), c.Raw, client)
+	})
+	rt.Handle("/listas", "Lista as listas existentes na conversa.", func(ctx context.Context, c *router.Context) error {
+		return handleListas(ctx, msgr, parseChatKey(c.ChatID), client)
+	})
+	rt.Handle("/migrarresumo", fmt.Sprintf("Importa o resumo antigo compartilhado para a lista %q desta conversa.", defaultListName), func(ctx context.Context, c *router.Context) error {
+		return handleMigrarResumo(ctx, msgr, parseChatKey(c.ChatID), client)
+	})
+	rt.Handle("/limpar", "Remove todos os itens da lista ativa.", func(ctx context.Context, c *router.Context) error {
+		return handleLimpar(ctx, msgr, parseChatKey(c.ChatID), client)
+	})
+	rt.Handle("/help", "Lista os comandos disponíveis.", func(ctx context.Context, c *router.Context) error {
+		return msgr.Send(ctx, parseChatKey(c.ChatID), rt.Help())
+	})
+	rt.Handle("/whoami", "Mostra o ID desta conversa, para configurar a allowlist.", func(ctx context.Context, c *router.Context) error {
+		chat := parseChatKey(c.ChatID)
+		return msgr.Send(ctx, chat, fmt.Sprintf("Chat ID: %s", chat.ID))
+	})
 
-	// Make sure `/r` is checked after `/resumo` or the logic will break.
-	if strings.HasPrefix(reqBody.Message.Text, "/r") {
-		handleAddResumo(ctx, reqBody.Message.Chat.Id, reqBody.Message.Text, config.TelegramToken, client)
-		return
+	return rt
+}
+
+// parseChatKey undoes chatKey, splitting a "platform:id" router.Context.ChatID back into its
+// messenger.ChatRef. Safe because chatKey is the only thing that ever produces a ChatID.
+func parseChatKey(key string) messenger.ChatRef {
+	platform, id, _ := strings.Cut(key, ":")
+	return messenger.ChatRef{Platform: platform, ID: id}
+}
+
+// authorizedChatKeys converts the legacy Telegram-numeric allowlist from Config into the
+// platform-qualified chat keys the router's AllowlistMiddleware compares against.
+func authorizedChatKeys(chatIDs []int) []string {
+	keys := make([]string, len(chatIDs))
+	for i, id := range chatIDs {
+		keys[i] = chatKey(telegram.ChatRef(id))
 	}
+	return keys
 }
 
-func handleHello(chatID int, message, token string) {
-	sendMessage(chatID, "hello!", token)
+func handleHello(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef) error {
+	return msgr.Send(ctx, chat, "hello!")
 }
 
-func handleSafada(chatID int, message, token string) {
-	sendMessage(chatID, "é você!", token)
+func handleSafada(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef) error {
+	return msgr.Send(ctx, chat, "é você!")
 }
 
-func handleResumo(ctx context.Context, chatID int, token string, client *firestore.Client) {
-	iter := client.Collection("summary").Documents(ctx)
+func handleResumo(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, client *firestore.Client) error {
+	listName, err := activeListName(ctx, client, chat)
+	if err != nil {
+		return fmt.Errorf("resolve active list: %w", err)
+	}
+
+	iter := client.Collection(itemsCollectionPath(chat, listName)).Documents(ctx)
 	var b strings.Builder
 	for {
 		doc, err := iter.Next()
@@ -143,49 +232,365 @@ func handleResumo(ctx context.Context, chatID int, token string, client *firesto
 			break
 		}
 		if err != nil {
-			log.Printf("failed to iterate: %v\n", err)
-			return
+			return fmt.Errorf("iterate items: %w", err)
 		}
 		var item SummaryItem
-		err = doc.DataTo(&item)
-		if err != nil {
-			log.Printf("failed parse item: %v\n", err)
-			return
+		if err := doc.DataTo(&item); err != nil {
+			return fmt.Errorf("parse item: %w", err)
 		}
 
 		b.Grow(len(item.Message))
 		b.WriteString("- " + item.Message + "\n")
 	}
 
-	sendMessage(chatID, b.String(), token)
+	return msgr.Send(ctx, chat, b.String())
 }
 
-func handleAddResumo(ctx context.Context, chatID int, message, token string, client *firestore.Client) {
+func handleAddResumo(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, authorID, message string, client *firestore.Client) error {
+	listName, err := activeListName(ctx, client, chat)
+	if err != nil {
+		return fmt.Errorf("resolve active list: %w", err)
+	}
+
 	split := strings.Split(message, " ")
 	newEntry := strings.Join(split[1:], " ")
-	_, _, err := client.Collection("summary").Add(ctx, SummaryItem{Message: newEntry})
-	if err != nil {
+	item := SummaryItem{
+		Message:   newEntry,
+		ChatID:    chat.ID,
+		Platform:  chat.Platform,
+		ListName:  listName,
+		AuthorID:  authorID,
+		CreatedAt: time.Now(),
+	}
+	if _, _, err := client.Collection(itemsCollectionPath(chat, listName)).Add(ctx, item); err != nil {
 		log.Printf("Falied to add message: %v\n", err)
-		sendMessage(chatID, "Ops! O código do Caio não funcionou :)", token)
-		return
+		return msgr.Send(ctx, chat, "Ops! O código do Caio não funcionou :)")
 	}
+	fireOnAdd(ctx, item)
 
-	successMsg := fmt.Sprintf("Adicionado ao resumo: %v", newEntry)
-	sendMessage(chatID, successMsg, token)
+	return msgr.Send(ctx, chat, fmt.Sprintf("Adicionado ao resumo: %v", newEntry))
 }
 
-func sendMessage(chatID int, message, token string) {
-	var telegramApi string = "https://api.telegram.org/bot" + token + "/sendMessage"
-	response, err := http.PostForm(
-		telegramApi,
-		url.Values{
-			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {message},
-		})
+// handleRm renders the active list as an inline keyboard; tapping an item removes it.
+func handleRm(ctx context.Context, tg *telegram.Client, chat messenger.ChatRef, client *firestore.Client) error {
+	listName, err := activeListName(ctx, client, chat)
+	if err != nil {
+		return fmt.Errorf("resolve active list: %w", err)
+	}
+
+	text, keyboard, err := rmKeyboardFor(ctx, client, chat, listName)
+	if err != nil {
+		return fmt.Errorf("build removal keyboard: %w", err)
+	}
+
+	chatID, err := strconv.Atoi(chat.ID)
+	if err != nil {
+		return fmt.Errorf("parse telegram chat id %q: %w", chat.ID, err)
+	}
+	return tg.SendWithKeyboard(chatID, text, keyboard)
+}
+
+// handleRmCallback handles the callback fired when a user taps an item on the `/rm` keyboard.
+func handleRmCallback(ctx context.Context, tg *telegram.Client, cq *telegram.CallbackQuery, client *firestore.Client) error {
+	defer tg.AnswerCallbackQuery(cq.Id)
+
+	listName, itemID, ok := parseRmCallbackData(cq.Data)
+	if !ok {
+		return nil
+	}
+
+	chat := telegram.ChatRef(cq.Message.Chat.Id)
+	docRef := client.Collection(itemsCollectionPath(chat, listName)).Doc(itemID)
+
+	doc, err := docRef.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("load item %s from list %q: %w", itemID, listName, err)
+	}
+	var removed SummaryItem
+	if err := doc.DataTo(&removed); err != nil {
+		return fmt.Errorf("parse item %s from list %q: %w", itemID, listName, err)
+	}
+
+	if _, err := docRef.Delete(ctx); err != nil {
+		return fmt.Errorf("remove item %s from list %q: %w", itemID, listName, err)
+	}
+	fireOnRemove(ctx, removed)
+
+	text, keyboard, err := rmKeyboardFor(ctx, client, chat, listName)
+	if err != nil {
+		return fmt.Errorf("rebuild removal keyboard: %w", err)
+	}
+
+	return tg.EditMessageText(cq.Message.Chat.Id, cq.Message.MessageID, text, keyboard)
+}
+
+// rmKeyboardFor builds the message text and inline keyboard for the current state of a list.
+func rmKeyboardFor(ctx context.Context, client *firestore.Client, chat messenger.ChatRef, listName string) (string, telegram.InlineKeyboardMarkup, error) {
+	iter := client.Collection(itemsCollectionPath(chat, listName)).Documents(ctx)
+	var keyboard telegram.InlineKeyboardMarkup
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", keyboard, err
+		}
+		var item SummaryItem
+		if err := doc.DataTo(&item); err != nil {
+			return "", keyboard, err
+		}
+
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegram.InlineKeyboardButton{{
+			Text:         item.Message,
+			CallbackData: fmt.Sprintf("rm:%s:%s", listName, doc.Ref.ID),
+		}})
+	}
+
+	if len(keyboard.InlineKeyboard) == 0 {
+		return "Nada para remover.", keyboard, nil
+	}
+
+	return "Toque em um item para remover:", keyboard, nil
+}
+
+func parseRmCallbackData(data string) (listName, itemID string, ok bool) {
+	parts := strings.SplitN(data, ":", 3)
+	if len(parts) != 3 || parts[0] != "rm" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// handleLista switches the active list for the current chat, creating it if needed.
+func handleLista(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, message string, client *firestore.Client) error {
+	split := strings.Split(message, " ")
+	if len(split) < 2 || strings.TrimSpace(split[1]) == "" {
+		return msgr.Send(ctx, chat, "Uso: /lista <nome>")
+	}
+	listName := strings.TrimSpace(split[1])
 
+	if err := ensureListExists(ctx, client, chat, listName); err != nil {
+		return fmt.Errorf("create list %q: %w", listName, err)
+	}
+	if err := setActiveList(ctx, client, chat, listName); err != nil {
+		return fmt.Errorf("set active list %q: %w", listName, err)
+	}
+
+	return msgr.Send(ctx, chat, fmt.Sprintf("Lista ativa: %v", listName))
+}
+
+// handleListas enumerates the lists that exist for the current chat.
+func handleListas(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, client *firestore.Client) error {
+	iter := client.Collection(summariesCollectionPath(chat)).Documents(ctx)
+	var b strings.Builder
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iterate lists: %w", err)
+		}
+		var list summaryList
+		if err := doc.DataTo(&list); err != nil {
+			return fmt.Errorf("parse list: %w", err)
+		}
+		b.WriteString("- " + list.Name + "\n")
+	}
+
+	if b.Len() == 0 {
+		return msgr.Send(ctx, chat, "Nenhuma lista encontrada.")
+	}
+
+	return msgr.Send(ctx, chat, b.String())
+}
+
+// handleMigrarResumo explicitly triggers migrateLegacySummary for the requesting chat. The
+// migration is gated behind this command, rather than running implicitly off of /resumo or /r,
+// because it permanently moves (and deletes) the old shared "summary" collection into whichever
+// chat runs it first; it must be a deliberate, allowlisted action, not a side effect of the first
+// message any chat happens to send after deploy.
+func handleMigrarResumo(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, client *firestore.Client) error {
+	migrated, err := migrateLegacySummary(ctx, client, chat)
+	if err != nil {
+		return fmt.Errorf("migrate legacy summary: %w", err)
+	}
+	if !migrated {
+		return msgr.Send(ctx, chat, "Nada para migrar: o resumo antigo já foi migrado.")
+	}
+
+	return msgr.Send(ctx, chat, fmt.Sprintf("Resumo antigo migrado para a lista %q desta conversa.", defaultListName))
+}
+
+// handleLimpar removes every item from the active list and fires SummaryHook.OnClear.
+func handleLimpar(ctx context.Context, msgr messenger.Messenger, chat messenger.ChatRef, client *firestore.Client) error {
+	listName, err := activeListName(ctx, client, chat)
 	if err != nil {
-		return // TODO: return friendly bot message for error cases
+		return fmt.Errorf("resolve active list: %w", err)
 	}
 
-	defer response.Body.Close()
+	if err := clearList(ctx, client, chat, listName); err != nil {
+		return fmt.Errorf("clear list %q: %w", listName, err)
+	}
+	fireOnClear(ctx, chat)
+
+	return msgr.Send(ctx, chat, fmt.Sprintf("Lista %q limpa.", listName))
+}
+
+// clearList deletes every item document in a chat's list.
+func clearList(ctx context.Context, client *firestore.Client, chat messenger.ChatRef, listName string) error {
+	iter := client.Collection(itemsCollectionPath(chat, listName)).Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chatKey is the platform-qualified identifier used both as the Firestore path segment for a
+// chat and as the router's per-chat key, so chats on different platforms never collide.
+func chatKey(chat messenger.ChatRef) string {
+	return chat.Platform + ":" + chat.ID
+}
+
+func summariesCollectionPath(chat messenger.ChatRef) string {
+	return fmt.Sprintf("chats/%s/summaries", chatKey(chat))
+}
+
+func itemsCollectionPath(chat messenger.ChatRef, listName string) string {
+	return fmt.Sprintf("chats/%s/summaries/%s/items", chatKey(chat), listName)
+}
+
+func chatDoc(client *firestore.Client, chat messenger.ChatRef) *firestore.DocumentRef {
+	return client.Collection("chats").Doc(chatKey(chat))
+}
+
+func activeListName(ctx context.Context, client *firestore.Client, chat messenger.ChatRef) (string, error) {
+	doc, err := chatDoc(client, chat).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return defaultListName, nil
+		}
+		return "", err
+	}
+
+	var state chatState
+	if err := doc.DataTo(&state); err != nil {
+		return "", err
+	}
+	if state.ActiveList == "" {
+		return defaultListName, nil
+	}
+	return state.ActiveList, nil
+}
+
+func setActiveList(ctx context.Context, client *firestore.Client, chat messenger.ChatRef, listName string) error {
+	_, err := chatDoc(client, chat).Set(ctx, chatState{ActiveList: listName}, firestore.MergeAll)
+	return err
+}
+
+func ensureListExists(ctx context.Context, client *firestore.Client, chat messenger.ChatRef, listName string) error {
+	_, err := client.Collection(summariesCollectionPath(chat)).Doc(listName).Set(ctx, summaryList{
+		Name:      listName,
+		CreatedAt: time.Now(),
+	}, firestore.MergeAll)
+	return err
+}
+
+// migrateLegacySummary moves documents from the old global "summary" collection into chat's
+// default list, when handleMigrarResumo is run for the very first time since deploy. The legacy
+// items carried no chat or platform association, so this is an explicit, one-time, best-effort
+// move that a chat's operator has to deliberately trigger: unlike the old implicit version of
+// this migration, it no longer happens as a side effect of any chat's first /resumo or /r, since
+// that silently handed the entire shared history to whichever chat happened to go first and was
+// unrecoverable for everyone else. migrated is false (with a nil error) if the migration already
+// ran for a previous chat, or if another chat won a concurrent race to run it.
+func migrateLegacySummary(ctx context.Context, client *firestore.Client, chat messenger.ChatRef) (migrated bool, err error) {
+	marker := client.Collection("migrations").Doc("legacySummary")
+	claimed, err := claimMigration(ctx, client, marker, chat)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	iter := client.Collection(legacySummaryCollection).Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+
+		var item SummaryItem
+		if err := doc.DataTo(&item); err != nil {
+			return false, err
+		}
+		item.ChatID = chat.ID
+		item.Platform = chat.Platform
+		item.ListName = defaultListName
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = time.Now()
+		}
+
+		if _, _, err := client.Collection(itemsCollectionPath(chat, defaultListName)).Add(ctx, item); err != nil {
+			return false, err
+		}
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	if err := ensureListExists(ctx, client, chat, defaultListName); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// errAlreadyMigrated signals that claimMigration found the marker already in place; it never
+// escapes claimMigration.
+var errAlreadyMigrated = errors.New("legacy summary already migrated")
+
+// claimMigration atomically checks for marker's existence and creates it in a single Firestore
+// transaction, so two chats racing handleMigrarResumo at the same time can't both observe the
+// marker missing and both proceed to copy the same legacy documents: at most one transaction's
+// Create can ever commit, and Firestore transparently retries the loser against the committed
+// state, so it always lands on the already-exists branch instead of racing past it. The marker is
+// written before the copy itself runs (rather than after, as the old non-transactional check did)
+// so the claim is what's atomic, not the whole migration; a chat that crashes mid-copy after
+// claiming will not get a second, retried attempt, which is an acceptable trade-off for a
+// one-time best-effort move.
+func claimMigration(ctx context.Context, client *firestore.Client, marker *firestore.DocumentRef, chat messenger.ChatRef) (bool, error) {
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(marker); err == nil {
+			return errAlreadyMigrated
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		return tx.Create(marker, map[string]interface{}{
+			"migratedAt": time.Now(),
+			"chatID":     chat.ID,
+			"platform":   chat.Platform,
+		})
+	})
+	if errors.Is(err, errAlreadyMigrated) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
@@ -0,0 +1,53 @@
+package dolista
+
+import (
+	"context"
+	"log"
+
+	"github.com/iatistas/dolista-safado/messenger"
+)
+
+// SummaryHook observes summary list mutations so integrations (audit trails, broadcasts, future
+// email digests or outbound webhooks) can react without handleAddResumo and friends knowing
+// anything about them.
+type SummaryHook interface {
+	OnAdd(ctx context.Context, item SummaryItem) error
+	OnRemove(ctx context.Context, item SummaryItem) error
+	OnClear(ctx context.Context, chat messenger.ChatRef) error
+}
+
+// registeredHooks holds every hook registered via RegisterHook, fired in registration order.
+var registeredHooks []SummaryHook
+
+// RegisterHook adds a hook to the pipeline. Call it at init time, before serving any requests.
+func RegisterHook(hook SummaryHook) {
+	registeredHooks = append(registeredHooks, hook)
+}
+
+// fireOnAdd notifies every registered hook that item was added. A failing hook is logged and
+// skipped rather than failing the command that triggered it.
+func fireOnAdd(ctx context.Context, item SummaryItem) {
+	for _, hook := range registeredHooks {
+		if err := hook.OnAdd(ctx, item); err != nil {
+			log.Printf("hook OnAdd failed: %v\n", err)
+		}
+	}
+}
+
+// fireOnRemove notifies every registered hook that item was removed.
+func fireOnRemove(ctx context.Context, item SummaryItem) {
+	for _, hook := range registeredHooks {
+		if err := hook.OnRemove(ctx, item); err != nil {
+			log.Printf("hook OnRemove failed: %v\n", err)
+		}
+	}
+}
+
+// fireOnClear notifies every registered hook that chat's active list was cleared.
+func fireOnClear(ctx context.Context, chat messenger.ChatRef) {
+	for _, hook := range registeredHooks {
+		if err := hook.OnClear(ctx, chat); err != nil {
+			log.Printf("hook OnClear failed: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package discord implements messenger.Messenger on top of DiscordGo. A Discord channel maps
+// 1:1 to a messenger.ChatRef.
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/iatistas/dolista-safado/messenger"
+)
+
+// Platform is the messenger.ChatRef.Platform value used for Discord channels.
+const Platform = "discord"
+
+// Client implements messenger.Messenger on top of a DiscordGo session.
+type Client struct {
+	session *discordgo.Session
+}
+
+// New creates a Client authenticated with a bot token.
+func New(token string) (*Client, error) {
+	session, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discord session: %w", err)
+	}
+	return &Client{session: session}, nil
+}
+
+// ChatRef builds the messenger.ChatRef for a Discord channel ID.
+func ChatRef(channelID string) messenger.ChatRef {
+	return messenger.ChatRef{Platform: Platform, ID: channelID}
+}
+
+func (c *Client) Send(ctx context.Context, chat messenger.ChatRef, text string) error {
+	_, err := c.session.ChannelMessageSend(chat.ID, text)
+	return err
+}
+
+// Receive opens the Discord gateway connection and streams normalized message events until ctx
+// is canceled, at which point the session and the channel are closed. If the gateway connection
+// cannot be opened, the returned channel is already closed.
+func (c *Client) Receive(ctx context.Context) <-chan messenger.Event {
+	events := make(chan messenger.Event)
+
+	c.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.Author.Bot {
+			return
+		}
+		select {
+		case events <- messenger.Event{
+			Chat:   ChatRef(m.ChannelID),
+			UserID: m.Author.ID,
+			Text:   m.Content,
+			Raw:    m,
+		}:
+		case <-ctx.Done():
+		}
+	})
+
+	if err := c.session.Open(); err != nil {
+		close(events)
+		return events
+	}
+
+	go func() {
+		<-ctx.Done()
+		c.session.Close()
+		close(events)
+	}()
+
+	return events
+}
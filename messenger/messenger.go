@@ -0,0 +1,28 @@
+// Package messenger abstracts the chat platform a bot talks over, so command handlers don't
+// need to know whether they're talking to Telegram, Discord, or anything else.
+package messenger
+
+import "context"
+
+// ChatRef identifies a conversation on a specific messaging platform, e.g. a Telegram chat ID
+// or a Discord channel ID.
+type ChatRef struct {
+	Platform string
+	ID       string
+}
+
+// Event is a normalized inbound message from any Messenger implementation.
+type Event struct {
+	Chat   ChatRef
+	UserID string
+	Text   string
+	Raw    any
+}
+
+// Messenger is implemented once per chat platform.
+type Messenger interface {
+	// Send delivers a text message to the given chat.
+	Send(ctx context.Context, chat ChatRef, text string) error
+	// Receive streams normalized inbound events until ctx is canceled.
+	Receive(ctx context.Context) <-chan Event
+}
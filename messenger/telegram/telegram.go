@@ -0,0 +1,168 @@
+// Package telegram implements messenger.Messenger on top of Telegram's Bot HTTP API.
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/iatistas/dolista-safado/messenger"
+)
+
+// Platform is the messenger.ChatRef.Platform value used for Telegram chats.
+const Platform = "telegram"
+
+// Update is the object Telegram delivers to the webhook on every interaction.
+type Update struct {
+	UpdateId      int            `json:"update_id"`
+	Message       Message        `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
+}
+
+// Message is a Telegram object that can be found in an update.
+type Message struct {
+	MessageID int    `json:"message_id"`
+	Text      string `json:"text"`
+	Chat      Chat   `json:"chat"`
+	From      From   `json:"from"`
+}
+
+// Chat indicates the conversation to which a message belongs.
+type Chat struct {
+	Id int `json:"id"`
+}
+
+// From identifies the Telegram user that sent a message.
+type From struct {
+	Id int `json:"id"`
+}
+
+// CallbackQuery is sent by Telegram when a user taps an inline keyboard button.
+type CallbackQuery struct {
+	Id      string  `json:"id"`
+	Data    string  `json:"data"`
+	Message Message `json:"message"`
+}
+
+// InlineKeyboardButton is a single Telegram inline keyboard button.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// InlineKeyboardMarkup is the reply_markup payload Telegram expects for inline keyboards.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// Client implements messenger.Messenger against Telegram's Bot HTTP API, plus a few
+// Telegram-only extensions (inline keyboards, callback queries) that don't fit the generic
+// interface.
+type Client struct {
+	Token string
+}
+
+// New creates a Client authenticated with a bot token.
+func New(token string) *Client {
+	return &Client{Token: token}
+}
+
+// ChatRef builds the messenger.ChatRef for a Telegram chat ID.
+func ChatRef(chatID int) messenger.ChatRef {
+	return messenger.ChatRef{Platform: Platform, ID: strconv.Itoa(chatID)}
+}
+
+// DecodeUpdate parses a webhook request body into an Update and its normalized Event.
+func DecodeUpdate(body io.Reader) (Update, messenger.Event, error) {
+	var update Update
+	if err := json.NewDecoder(body).Decode(&update); err != nil {
+		return Update{}, messenger.Event{}, err
+	}
+
+	event := messenger.Event{
+		Chat:   ChatRef(update.Message.Chat.Id),
+		UserID: strconv.Itoa(update.Message.From.Id),
+		Text:   update.Message.Text,
+		Raw:    update,
+	}
+	return update, event, nil
+}
+
+func (c *Client) Send(ctx context.Context, chat messenger.ChatRef, text string) error {
+	return c.sendMessage(chat.ID, text, nil)
+}
+
+// Receive is a no-op: Telegram pushes updates to our webhook instead of us polling for them, so
+// inbound events arrive through DecodeUpdate rather than this channel.
+func (c *Client) Receive(ctx context.Context) <-chan messenger.Event {
+	ch := make(chan messenger.Event)
+	close(ch)
+	return ch
+}
+
+// SendWithKeyboard sends a message with an inline keyboard attached.
+func (c *Client) SendWithKeyboard(chatID int, text string, keyboard InlineKeyboardMarkup) error {
+	return c.sendMessage(strconv.Itoa(chatID), text, &keyboard)
+}
+
+// EditMessageText edits a previously sent message in place, replacing its keyboard.
+func (c *Client) EditMessageText(chatID, messageID int, text string, keyboard InlineKeyboardMarkup) error {
+	markup, err := json.Marshal(keyboard)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.PostForm(
+		c.apiURL("editMessageText"),
+		url.Values{
+			"chat_id":      {strconv.Itoa(chatID)},
+			"message_id":   {strconv.Itoa(messageID)},
+			"text":         {text},
+			"reply_markup": {string(markup)},
+		})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return nil
+}
+
+// AnswerCallbackQuery acknowledges a callback query so Telegram stops showing the loading spinner.
+func (c *Client) AnswerCallbackQuery(callbackQueryID string) error {
+	response, err := http.PostForm(
+		c.apiURL("answerCallbackQuery"),
+		url.Values{"callback_query_id": {callbackQueryID}})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return nil
+}
+
+func (c *Client) sendMessage(chatID, text string, keyboard *InlineKeyboardMarkup) error {
+	values := url.Values{
+		"chat_id": {chatID},
+		"text":    {text},
+	}
+	if keyboard != nil {
+		markup, err := json.Marshal(keyboard)
+		if err != nil {
+			return err
+		}
+		values.Set("reply_markup", string(markup))
+	}
+
+	response, err := http.PostForm(c.apiURL("sendMessage"), values)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return nil
+}
+
+func (c *Client) apiURL(method string) string {
+	return "https://api.telegram.org/bot" + c.Token + "/" + method
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// LoggingMiddleware logs every dispatched command with its chat and arguments.
+func LoggingMiddleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, c *Context) error {
+			log.Printf("router: chat=%s command=%s args=%v\n", c.ChatID, c.Command, c.Args)
+			return next(ctx, c)
+		}
+	}
+}
+
+// RateLimitMiddleware rejects a chat's commands once it exceeds `limit` invocations within
+// `window`, independently per chat.
+func RateLimitMiddleware(limit int, window time.Duration) Middleware {
+	var mu sync.Mutex
+	hits := make(map[string][]time.Time)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, c *Context) error {
+			now := time.Now()
+
+			mu.Lock()
+			var recent []time.Time
+			for _, t := range hits[c.ChatID] {
+				if now.Sub(t) < window {
+					recent = append(recent, t)
+				}
+			}
+			if len(recent) >= limit {
+				hits[c.ChatID] = recent
+				mu.Unlock()
+				return fmt.Errorf("rate limit exceeded for chat %s", c.ChatID)
+			}
+			hits[c.ChatID] = append(recent, now)
+			mu.Unlock()
+
+			return next(ctx, c)
+		}
+	}
+}
+
+// AllowlistMiddleware rejects commands from chats not present in allowed. An empty allowlist
+// disables the check, so bots that haven't configured one keep working unrestricted. Commands
+// named in exempt always run regardless of chat, e.g. "/whoami", which exists to help an operator
+// learn a new chat's ID so they can add it to the allowlist in the first place.
+func AllowlistMiddleware(allowed []string, exempt ...string) Middleware {
+	exemptSet := make(map[string]struct{}, len(exempt))
+	for _, name := range exempt {
+		exemptSet[name] = struct{}{}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, c *Context) error {
+			if _, ok := exemptSet[c.Command]; ok {
+				return next(ctx, c)
+			}
+			if !IsAuthorized(allowed, c.ChatID) {
+				return fmt.Errorf("chat %s is not authorized", c.ChatID)
+			}
+			return next(ctx, c)
+		}
+	}
+}
+
+// IsAuthorized reports whether chatID is present in allowed, the same check AllowlistMiddleware
+// applies to dispatched commands. An empty allowed list authorizes every chat. Exported so callers
+// that act on chat input outside of Dispatch (e.g. a platform's inline callback/button handling)
+// can apply the same allowlist without duplicating it.
+func IsAuthorized(allowed []string, chatID string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, id := range allowed {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
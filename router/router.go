@@ -0,0 +1,112 @@
+// Package router implements a small exact-match command dispatcher for chat bot updates,
+// replacing ad-hoc chains of strings.HasPrefix checks (and the ordering bugs those invite).
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HandlerFunc handles a single command invocation.
+type HandlerFunc func(ctx context.Context, c *Context) error
+
+// Context carries the parsed command and per-update state handed to a HandlerFunc.
+type Context struct {
+	ChatID  string
+	UserID  string
+	Command string
+	Args    []string
+	Raw     string
+}
+
+// Middleware wraps a HandlerFunc to add cross-cutting behavior such as logging or rate limiting.
+type Middleware func(HandlerFunc) HandlerFunc
+
+type command struct {
+	name        string
+	description string
+	handler     HandlerFunc
+}
+
+// CommandRouter tokenizes incoming text, matches it against registered commands by exact name,
+// and dispatches to the matching handler through the configured middleware chain.
+type CommandRouter struct {
+	mu         sync.RWMutex
+	commands   map[string]command
+	middleware []Middleware
+}
+
+// New creates an empty CommandRouter.
+func New() *CommandRouter {
+	return &CommandRouter{commands: make(map[string]command)}
+}
+
+// Use appends middleware to the chain. Middleware runs in the order it was added, outermost first.
+func (r *CommandRouter) Use(mw Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw)
+}
+
+// Handle registers a handler for an exact command name, e.g. "/resumo".
+func (r *CommandRouter) Handle(name, description string, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[name] = command{name: name, description: description, handler: handler}
+}
+
+// Dispatch tokenizes text and routes it to the matching command, applying all middleware.
+// It returns matched=false when no registered command matches, so callers can decide whether
+// an unrecognized message deserves a reply.
+func (r *CommandRouter) Dispatch(ctx context.Context, chatID, userID string, text string) (matched bool, err error) {
+	name, args := tokenize(text)
+	if name == "" {
+		return false, nil
+	}
+
+	r.mu.RLock()
+	match, ok := r.commands[name]
+	r.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	handler := match.handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+
+	c := &Context{ChatID: chatID, UserID: userID, Command: name, Args: args, Raw: text}
+	return true, handler(ctx, c)
+}
+
+// Help returns a sorted "/command - description" listing of every registered command.
+func (r *CommandRouter) Help() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf("%s - %s\n", name, r.commands[name].description))
+	}
+	return b.String()
+}
+
+// tokenize splits "/resumo foo bar" into command "/resumo" and args ["foo", "bar"]. Matching is
+// exact, so "/r" and "/resumo" never collide regardless of registration order.
+func tokenize(text string) (name string, args []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+	return fields[0], fields[1:]
+}